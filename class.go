@@ -0,0 +1,128 @@
+package rate
+
+import "time"
+
+// Class identifies a priority tier an entity belongs to, e.g. free vs paid,
+// publish vs read, or admin vs anon.
+type Class string
+
+// ClassRefiller is an optional interface a [Refiller] can implement to
+// support [Limiter.AllowClass], letting the caller supply the priority class
+// explicitly instead of it being derived from the entity. It is detected
+// with a type assertion, so implementing it is never required.
+type ClassRefiller[K comparable] interface {
+	// NewBucketClass creates a fully initialized Bucket for entity, scaled
+	// for class.
+	NewBucketClass(entity K, class Class) *Bucket
+
+	// RefillClass updates bucket as if entity belonged to class, regardless
+	// of what the Refiller would otherwise derive for it.
+	RefillClass(entity K, bucket *Bucket, class Class)
+}
+
+// ClassifiedRefiller wraps a base [FlatRefiller] and scales its MaxTokens and
+// TokensPerInterval by a per-[Class] multiplier, so a single [Limiter] can
+// serve several priority tiers (e.g. 25x the default budget for priority
+// publishers) without instantiating one Limiter per class.
+//
+// Classes without an entry in Multipliers get a multiplier of 1, i.e. the
+// base rate.
+//
+// Base.Cooldown is honored: a denied entity's balance is driven negative and
+// clamped at Base.MinBalance scaled by its class multiplier, exactly as plain
+// [FlatRefiller] does for [Limiter.Allow]. [Limiter.AllowClass] applies the
+// same behavior, scaling Base.MinBalance by the class passed to it.
+type ClassifiedRefiller[K comparable] struct {
+	Base        FlatRefiller[K]
+	Classify    func(entity K) Class
+	Multipliers map[Class]float64
+}
+
+func (r ClassifiedRefiller[K]) multiplier(class Class) float64 {
+	if m, ok := r.Multipliers[class]; ok {
+		return m
+	}
+	return 1
+}
+
+func (r ClassifiedRefiller[K]) NewBucket(entity K) *Bucket {
+	return r.NewBucketClass(entity, r.Classify(entity))
+}
+
+func (r ClassifiedRefiller[K]) Refill(entity K, b *Bucket) {
+	r.RefillClass(entity, b, r.Classify(entity))
+}
+
+func (r ClassifiedRefiller[K]) NewBucketClass(_ K, class Class) *Bucket {
+	m := r.multiplier(class)
+	return &Bucket{
+		Tokens:     r.Base.InitialTokens * m,
+		LastRefill: time.Now(),
+	}
+}
+
+func (r ClassifiedRefiller[K]) RefillClass(_ K, b *Bucket, class Class) {
+	if r.Base.Interval <= 0 {
+		return
+	}
+	refills := time.Since(b.LastRefill) / r.Base.Interval
+	if refills == 0 {
+		return
+	}
+
+	m := r.multiplier(class)
+	b.Tokens = min(r.Base.MaxTokens*m, b.Tokens+float64(refills)*r.Base.TokensPerInterval*m)
+	b.LastRefill = b.LastRefill.Add(refills * r.Base.Interval)
+}
+
+// OnDeny implements [Denier], forwarding to Base: when Base.Cooldown is
+// enabled, it deducts cost from bucket even though the request was denied,
+// clamped at Base.MinBalance scaled by entity's class multiplier.
+func (r ClassifiedRefiller[K]) OnDeny(entity K, b *Bucket, cost float64) {
+	r.onDenyClass(b, cost, r.Classify(entity))
+}
+
+// onDenyClass is OnDeny with the class supplied by the caller.
+func (r ClassifiedRefiller[K]) onDenyClass(b *Bucket, cost float64, class Class) {
+	if !r.Base.Cooldown {
+		return
+	}
+	m := r.multiplier(class)
+	b.Tokens = max(r.Base.MinBalance*m, b.Tokens-cost)
+}
+
+// AllowClass behaves like [Limiter.Allow], but uses class directly instead of
+// letting the Refiller derive it from entity. Use it when the class is
+// already known at the call site (e.g. the request path) rather than
+// recoverable from the key alone. It panics if the cost is negative, or if
+// the configured Refiller does not implement [ClassRefiller].
+func (l *Limiter[K]) AllowClass(entity K, cost float64, class Class) bool {
+	if cost < 0 {
+		panic("limiter.AllowClass: cost must be non-negative")
+	}
+	if cost == 0 {
+		return true
+	}
+
+	cr, ok := l.refiller.(ClassRefiller[K])
+	if !ok {
+		panic("limiter.AllowClass: configured Refiller does not implement ClassRefiller")
+	}
+
+	bucket := l.getOrCreateWith(entity, func() *Bucket { return cr.NewBucketClass(entity, class) })
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	cr.RefillClass(entity, bucket, class)
+	if bucket.Tokens < cost {
+		if c, ok := cr.(ClassifiedRefiller[K]); ok {
+			c.onDenyClass(bucket, cost, class)
+		} else if d, ok := cr.(Denier[K]); ok {
+			d.OnDeny(entity, bucket, cost)
+		}
+		return false
+	}
+	bucket.Tokens -= cost
+	return true
+}
@@ -0,0 +1,115 @@
+package rate
+
+import "testing"
+
+func TestClassifiedRefillerScalesByClass(t *testing.T) {
+	const (
+		free Class = "free"
+		paid Class = "paid"
+	)
+
+	refiller := ClassifiedRefiller[string]{
+		Base: FlatRefiller[string]{
+			InitialTokens: 10,
+			MaxTokens:     10,
+		},
+		Classify: func(entity string) Class {
+			if entity == "alice" {
+				return paid
+			}
+			return free
+		},
+		Multipliers: map[Class]float64{
+			paid: 25,
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	limiter.Allow("alice", 1) // touching the bucket creates it
+	if balance := limiter.Balance("alice"); balance != 249 {
+		t.Fatalf("expected paid entity to get 25x the base tokens, got %v", balance)
+	}
+
+	limiter.Allow("bob", 1)
+	if balance := limiter.Balance("bob"); balance != 9 {
+		t.Fatalf("expected unclassified entity to get the base tokens, got %v", balance)
+	}
+}
+
+func TestAllowClassOverridesClassify(t *testing.T) {
+	const admin Class = "admin"
+
+	refiller := ClassifiedRefiller[string]{
+		Base: FlatRefiller[string]{
+			InitialTokens: 10,
+			MaxTokens:     10,
+		},
+		Classify: func(_ string) Class { return "" },
+		Multipliers: map[Class]float64{
+			admin: 10,
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	if !limiter.AllowClass("carol", 100, admin) {
+		t.Fatalf("expected the admin class override to grant a 10x budget")
+	}
+}
+
+func TestClassifiedRefillerCooldown(t *testing.T) {
+	const paid Class = "paid"
+
+	refiller := ClassifiedRefiller[string]{
+		Base: FlatRefiller[string]{
+			InitialTokens: 10,
+			MaxTokens:     10,
+			Cooldown:      true,
+			MinBalance:    -10,
+		},
+		Classify: func(_ string) Class { return paid },
+		Multipliers: map[Class]float64{
+			paid: 2,
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	// initial balance is 20 (10 * 2x); deny a request for 30, driving the
+	// balance to 20-30=-10 (above MinBalance * 2x = -20, so unclamped).
+	if limiter.Allow("lewis", 30) {
+		t.Fatalf("expected lewis to be denied")
+	}
+	if balance := limiter.Balance("lewis"); balance != -10 {
+		t.Fatalf("expected cooldown to drive balance to -10, got %v", balance)
+	}
+
+	// lewis stays locked out even for a tiny request, unlike without cooldown
+	if limiter.Allow("lewis", 1) {
+		t.Fatalf("expected lewis to remain locked out during cooldown")
+	}
+}
+
+func TestAllowClassCooldown(t *testing.T) {
+	const admin Class = "admin"
+
+	refiller := ClassifiedRefiller[string]{
+		Base: FlatRefiller[string]{
+			InitialTokens: 10,
+			MaxTokens:     10,
+			Cooldown:      true,
+			MinBalance:    -5,
+		},
+		Classify: func(_ string) Class { return "" },
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	if limiter.AllowClass("dave", 20, admin) {
+		t.Fatalf("expected dave to be denied")
+	}
+	if balance := limiter.Balance("dave"); balance != -5 {
+		t.Fatalf("expected cooldown to drive balance to MinBalance via AllowClass, got %v", balance)
+	}
+}
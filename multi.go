@@ -0,0 +1,80 @@
+package rate
+
+// MultiBucket composes several independent Bucket dimensions (e.g. one for
+// operations/sec, one for bytes/sec) that must ALL be satisfied for a
+// request to be allowed. It is created by a [MultiRefiller] and consumed via
+// [Limiter.AllowN].
+type MultiBucket struct {
+	Buckets []*Bucket
+}
+
+// MultiRefiller composes N independent [Refiller]s into a single
+// multi-dimensional bucket. Use it together with [Limiter.AllowN] to require
+// that a request satisfy every dimension at once, e.g. Firecracker-style I/O
+// limiting where a request needs both an available operation and enough
+// bandwidth.
+type MultiRefiller[K comparable] struct {
+	Refillers []Refiller[K]
+}
+
+// NewBucket creates one sub-bucket per configured Refiller, via each
+// Refiller's own NewBucket.
+func (r MultiRefiller[K]) NewBucket(entity K) *Bucket {
+	sub := make([]*Bucket, len(r.Refillers))
+	for i, refiller := range r.Refillers {
+		sub[i] = refiller.NewBucket(entity)
+	}
+
+	return &Bucket{
+		multi: &MultiBucket{Buckets: sub},
+	}
+}
+
+// Refill refills every sub-bucket via its own Refiller.
+func (r MultiRefiller[K]) Refill(entity K, b *Bucket) {
+	if b.multi == nil {
+		return
+	}
+	for i, refiller := range r.Refillers {
+		refiller.Refill(entity, b.multi.Buckets[i])
+	}
+}
+
+// AllowN checks every sub-bucket of entity's multi-dimensional bucket against
+// the matching cost, and only if ALL of them can afford it, deducts every
+// cost atomically; otherwise no sub-bucket is touched. It panics if any cost
+// is negative, or if entity's bucket was not created by a [MultiRefiller]
+// configured with len(costs) dimensions.
+//
+// This lets a single [Limiter] enforce e.g. "<=500 req/s AND <=10 MiB/s per
+// key" atomically, which running two separate single-dimension Limiters
+// cannot guarantee.
+func (l *Limiter[K]) AllowN(entity K, costs ...float64) bool {
+	for _, cost := range costs {
+		if cost < 0 {
+			panic("limiter.AllowN: cost must be non-negative")
+		}
+	}
+
+	bucket := l.getOrCreate(entity)
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if bucket.multi == nil || len(bucket.multi.Buckets) != len(costs) {
+		panic("limiter.AllowN: entity's bucket was not created by a MultiRefiller with a matching number of dimensions")
+	}
+
+	l.refiller.Refill(entity, bucket)
+
+	for i, sub := range bucket.multi.Buckets {
+		if sub.Tokens < costs[i] {
+			return false
+		}
+	}
+
+	for i, sub := range bucket.multi.Buckets {
+		sub.Tokens -= costs[i]
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package rate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAllowNRequiresAllDimensions(t *testing.T) {
+	refiller := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 500, MaxTokens: 500}, // ops/s
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},   // MiB/s
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+	entity := "lewis"
+
+	// affordable on both dimensions
+	if !limiter.AllowN(entity, 1, 4) {
+		t.Fatalf("expected request within both budgets to be allowed")
+	}
+
+	// affordable on ops but not on bandwidth: neither dimension should be touched
+	if limiter.AllowN(entity, 1, 100) {
+		t.Fatalf("expected request exceeding the bandwidth budget to be denied")
+	}
+	if balances := limiter.BalanceN(entity); balances[0] != 499 || balances[1] != 6 {
+		t.Fatalf("expected the denied call above to have touched neither dimension, got %v", balances)
+	}
+
+	// the denied call above must not have deducted from either sub-bucket
+	if !limiter.AllowN(entity, 1, 6) {
+		t.Fatalf("expected the failed call above to have deducted nothing")
+	}
+}
+
+func TestAllowNPanicsOnDimensionMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected AllowN to panic on a dimension-count mismatch")
+		}
+	}()
+
+	refiller := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+	limiter.AllowN("lewis", 1, 2)
+}
+
+// TestGenericMethodsPanicOnMultiBucket checks that the single-balance methods
+// refuse to operate on a multi-dimensional bucket instead of silently
+// reading/writing the unused top-level Bucket.Tokens field.
+func TestGenericMethodsPanicOnMultiBucket(t *testing.T) {
+	refiller := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+		},
+	}
+
+	expectPanic := func(t *testing.T, name string, fn func()) {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected %s to panic on a multi-dimensional bucket", name)
+				}
+			}()
+			fn()
+		})
+	}
+
+	expectPanic(t, "Balance", func() {
+		limiter := NewLimiter[string](refiller)
+		limiter.AllowN("lewis", 1) // create the bucket so Balance reaches the multi check
+		limiter.Balance("lewis")
+	})
+	expectPanic(t, "Penalize", func() {
+		NewLimiter[string](refiller).Penalize("lewis", 1)
+	})
+	expectPanic(t, "Reward", func() {
+		NewLimiter[string](refiller).Reward("lewis", 1)
+	})
+	expectPanic(t, "Wait", func() {
+		NewLimiter[string](refiller).Wait(context.Background(), "lewis", 1)
+	})
+	expectPanic(t, "RetryAfter", func() {
+		NewLimiter[string](refiller).RetryAfter("lewis", 1)
+	})
+	expectPanic(t, "Reserve", func() {
+		NewLimiter[string](refiller).Reserve("lewis", 1)
+	})
+}
+
+// TestBalanceNPanicsOnOrdinaryBucket checks the inverse mismatch: BalanceN
+// requires a bucket created by a MultiRefiller.
+func TestBalanceNPanicsOnOrdinaryBucket(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected BalanceN to panic on an ordinary bucket")
+		}
+	}()
+
+	limiter := NewLimiter[string](FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10})
+	limiter.Allow("lewis", 1)
+	limiter.BalanceN("lewis")
+}
+
+func TestBalanceNReportsEachDimension(t *testing.T) {
+	refiller := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 500, MaxTokens: 500},
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+		},
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	if balances := limiter.BalanceN("lewis"); balances != nil {
+		t.Fatalf("expected nil balances for an entity with no bucket yet, got %v", balances)
+	}
+
+	if !limiter.AllowN("lewis", 1, 4) {
+		t.Fatalf("expected AllowN to succeed")
+	}
+	if balances := limiter.BalanceN("lewis"); balances[0] != 499 || balances[1] != 6 {
+		t.Fatalf("expected balances [499 6], got %v", balances)
+	}
+}
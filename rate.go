@@ -4,6 +4,7 @@
 package rate
 
 import (
+	"container/list"
 	"sync"
 	"time"
 )
@@ -14,6 +15,13 @@ type Limiter[K comparable] struct {
 	mu       sync.RWMutex
 	buckets  map[K]*Bucket
 	refiller Refiller[K]
+
+	// maxEntries bounds the number of tracked buckets. Zero means unbounded,
+	// and order/elements are left unused in that case.
+	maxEntries int
+	order      *list.List
+	elements   map[K]*list.Element
+	onEvict    func(entity K, bucket *Bucket)
 }
 
 // Bucket is a stateful token bucket for an entity.
@@ -21,9 +29,16 @@ type Bucket struct {
 	mu         sync.Mutex
 	Tokens     float64
 	LastRefill time.Time
+
+	// multi holds the per-dimension sub-buckets when this Bucket was created
+	// by a [MultiRefiller]. It is nil for an ordinary, single-dimension
+	// bucket, in which case Tokens/LastRefill above are used directly.
+	multi *MultiBucket
 }
 
 // NewLimiter creates a new limiter with the refill policy encoded in the [Refiller].
+// The returned limiter tracks buckets for every entity it ever sees; use
+// [NewBoundedLimiter] if the key space is unbounded or hostile.
 func NewLimiter[K comparable](r Refiller[K]) *Limiter[K] {
 	return &Limiter[K]{
 		buckets:  make(map[K]*Bucket, 1000),
@@ -31,6 +46,41 @@ func NewLimiter[K comparable](r Refiller[K]) *Limiter[K] {
 	}
 }
 
+// NewBoundedLimiter creates a new limiter that tracks at most maxEntries buckets,
+// evicting the least-recently-used entity when a new one would exceed the limit.
+// It panics if maxEntries is not positive.
+//
+// An entity evicted from the LRU is treated as having a full, fresh bucket the
+// next time it is seen, since its bucket is recreated via [Refiller.NewBucket].
+// This is the classic "precise for the most recent keys, assume the rest are
+// well-behaved" tradeoff, and bounds memory under high-cardinality or hostile
+// key churn (e.g. a client cycling through IPs).
+//
+// Use [Limiter.OnEvict] to observe evictions.
+func NewBoundedLimiter[K comparable](r Refiller[K], maxEntries int) *Limiter[K] {
+	if maxEntries <= 0 {
+		panic("rate.NewBoundedLimiter: maxEntries must be positive")
+	}
+	return &Limiter[K]{
+		buckets:    make(map[K]*Bucket, maxEntries),
+		refiller:   r,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		elements:   make(map[K]*list.Element, maxEntries),
+	}
+}
+
+// OnEvict registers a callback invoked whenever the LRU evicts an entity's
+// bucket to stay within maxEntries. It is a no-op on an unbounded limiter.
+//
+// The callback runs synchronously while the limiter's internal lock is held,
+// so it must not call back into the limiter itself.
+func (l *Limiter[K]) OnEvict(fn func(entity K, bucket *Bucket)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onEvict = fn
+}
+
 // Len returns the number of entities tracked by the limiter.
 func (l *Limiter[K]) Len() int {
 	l.mu.RLock()
@@ -40,6 +90,8 @@ func (l *Limiter[K]) Len() int {
 
 // Balance returns the number of tokens in the entity's bucket.
 // If the entity does not have a bucket yet, it returns 0.
+// It panics if entity's bucket was created by a [MultiRefiller], which has
+// no single balance to report; use [Limiter.BalanceN] instead.
 func (l *Limiter[K]) Balance(entity K) float64 {
 	l.mu.RLock()
 	bucket, exists := l.buckets[entity]
@@ -49,11 +101,54 @@ func (l *Limiter[K]) Balance(entity K) float64 {
 		return 0
 	}
 
+	if l.maxEntries > 0 {
+		l.mu.Lock()
+		l.touch(entity)
+		l.mu.Unlock()
+	}
+
 	bucket.mu.Lock()
 	defer bucket.mu.Unlock()
+
+	if bucket.multi != nil {
+		panic("limiter.Balance: entity's bucket was created by a MultiRefiller; use BalanceN instead")
+	}
 	return bucket.Tokens
 }
 
+// BalanceN returns the number of tokens in each dimension of the entity's
+// multi-dimensional bucket, in the same order as the [MultiRefiller]'s
+// Refillers. If the entity does not have a bucket yet, it returns nil.
+// It panics if entity's bucket was not created by a [MultiRefiller].
+func (l *Limiter[K]) BalanceN(entity K) []float64 {
+	l.mu.RLock()
+	bucket, exists := l.buckets[entity]
+	l.mu.RUnlock()
+
+	if !exists {
+		return nil
+	}
+
+	if l.maxEntries > 0 {
+		l.mu.Lock()
+		l.touch(entity)
+		l.mu.Unlock()
+	}
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if bucket.multi == nil {
+		panic("limiter.BalanceN: entity's bucket was not created by a MultiRefiller; use Balance instead")
+	}
+
+	balances := make([]float64, len(bucket.multi.Buckets))
+	for i, sub := range bucket.multi.Buckets {
+		balances[i] = sub.Tokens
+	}
+	return balances
+}
+
 // Allow returns true if the entity can afford the cost, false otherwise.
 // If the cost is affordable, it is deducted from the entity's bucket.
 // It panics if the cost is negative.
@@ -71,27 +166,16 @@ func (l *Limiter[K]) Allow(entity K, cost float64) bool {
 		return true
 	}
 
-	l.mu.RLock()
-	bucket, exists := l.buckets[entity]
-	l.mu.RUnlock()
-
-	if !exists {
-		l.mu.Lock()
-		// re-check while holding the write lock to avoid race conditions
-		// where the same entity is assigned a bucket multiple times
-		bucket, exists = l.buckets[entity]
-		if !exists {
-			bucket = l.refiller.NewBucket(entity)
-			l.buckets[entity] = bucket
-		}
-		l.mu.Unlock()
-	}
+	bucket := l.getOrCreate(entity)
 
 	bucket.mu.Lock()
 	defer bucket.mu.Unlock()
 
 	l.refiller.Refill(entity, bucket)
 	if bucket.Tokens < cost {
+		if d, ok := l.refiller.(Denier[K]); ok {
+			d.OnDeny(entity, bucket, cost)
+		}
 		return false
 	}
 	bucket.Tokens -= cost
@@ -101,7 +185,8 @@ func (l *Limiter[K]) Allow(entity K, cost float64) bool {
 // Penalize unconditionally deducts a cost from the entity's bucket.
 // Unlike [Limiter.Allow], no refill is applied and the deduction always occurs,
 // even if the resulting token balance becomes negative.
-// It panics if the cost is negative.
+// It panics if the cost is negative, or if entity's bucket was created by a
+// [MultiRefiller], which has no single balance to deduct from.
 //
 // If the entity does not have a bucket yet, one is created via [Refiller.NewBucket],
 // then the penalty is applied. This allows punishing entities detected through
@@ -117,7 +202,8 @@ func (l *Limiter[K]) Penalize(entity K, cost float64) {
 }
 
 // Reward unconditionally adds a number of tokens to the entity's bucket.
-// It panics if the reward is negative.
+// It panics if the reward is negative, or if entity's bucket was created by a
+// [MultiRefiller], which has no single balance to credit.
 //
 // If the entity does not have a bucket yet, one is created via [Refiller.NewBucket],
 // then the reward is applied. This allows rewarding entities detected through
@@ -133,28 +219,100 @@ func (l *Limiter[K]) Reward(entity K, reward float64) {
 }
 
 // Add unconditionally adds or deducts a number of tokens to the entity's bucket.
+// It panics if entity's bucket was created by a [MultiRefiller], since there
+// is no single balance to adjust; see [Limiter.AllowN] for that case.
 func (l *Limiter[K]) add(entity K, tokens float64) {
 	if tokens == 0 {
 		return
 	}
 
+	bucket := l.getOrCreate(entity)
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if bucket.multi != nil {
+		panic("limiter: Penalize/Reward cannot be used on a bucket created by a MultiRefiller; use AllowN instead")
+	}
+	bucket.Tokens += tokens
+}
+
+// getOrCreate returns the entity's bucket, creating one via the Refiller if needed.
+func (l *Limiter[K]) getOrCreate(entity K) *Bucket {
+	return l.getOrCreateWith(entity, func() *Bucket { return l.refiller.NewBucket(entity) })
+}
+
+// getOrCreateWith returns the entity's bucket, creating one via newBucket if
+// needed. On a bounded limiter, this also marks the entity as
+// most-recently-used, evicting the least-recently-used entry if the limiter
+// is at capacity.
+//
+// We don't consider creating a bucket for an unknown entity as an error,
+// because the decision to touch it could have been made elsewhere, e.g. an
+// external system detecting abuse.
+func (l *Limiter[K]) getOrCreateWith(entity K, newBucket func() *Bucket) *Bucket {
 	l.mu.RLock()
 	bucket, exists := l.buckets[entity]
 	l.mu.RUnlock()
 
-	if !exists {
-		// We don't consider adding tokens to an unknown entity as an error,
-		// because the decision could have been made elsewhere. E.g. an external system detecting abuse.
-		l.mu.Lock()
-		bucket, exists = l.buckets[entity]
-		if !exists {
-			bucket = l.refiller.NewBucket(entity)
-			l.buckets[entity] = bucket
+	if exists {
+		if l.maxEntries > 0 {
+			l.mu.Lock()
+			l.touch(entity)
+			l.mu.Unlock()
 		}
-		l.mu.Unlock()
+		return bucket
 	}
 
-	bucket.mu.Lock()
-	bucket.Tokens += tokens
-	bucket.mu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// re-check while holding the write lock to avoid race conditions
+	// where the same entity is assigned a bucket multiple times
+	if bucket, exists = l.buckets[entity]; exists {
+		if l.maxEntries > 0 {
+			l.touch(entity)
+		}
+		return bucket
+	}
+
+	bucket = newBucket()
+	l.buckets[entity] = bucket
+
+	if l.maxEntries > 0 {
+		l.elements[entity] = l.order.PushFront(entity)
+		if len(l.buckets) > l.maxEntries {
+			l.evictLRU()
+		}
+	}
+
+	return bucket
+}
+
+// touch marks entity as most-recently-used. Callers must hold l.mu and only
+// call this on a bounded limiter.
+func (l *Limiter[K]) touch(entity K) {
+	if elem, ok := l.elements[entity]; ok {
+		l.order.MoveToFront(elem)
+	}
+}
+
+// evictLRU removes the least-recently-used entity's bucket. Callers must hold
+// l.mu and only call this on a bounded limiter.
+func (l *Limiter[K]) evictLRU() {
+	elem := l.order.Back()
+	if elem == nil {
+		return
+	}
+
+	entity := elem.Value.(K)
+	bucket := l.buckets[entity]
+
+	l.order.Remove(elem)
+	delete(l.elements, entity)
+	delete(l.buckets, entity)
+
+	if l.onEvict != nil {
+		l.onEvict(entity, bucket)
+	}
 }
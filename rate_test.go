@@ -18,12 +18,7 @@ func TestAllow(t *testing.T) {
 	allowed := 0
 
 	for {
-		ok, err := limiter.Allow(entity, 1)
-		if err != nil {
-			t.Fatalf("failed to allow: %v", err)
-		}
-
-		if !ok {
+		if !limiter.Allow(entity, 1) {
 			break
 		}
 		allowed++
@@ -45,16 +40,10 @@ func TestPenalize(t *testing.T) {
 	entity := "lewis"
 
 	// Penalize the entity by 150 tokens (more than initial)
-	if err := limiter.Penalize(entity, 150); err != nil {
-		t.Fatalf("failed to penalize: %v", err)
-	}
+	limiter.Penalize(entity, 150)
 
 	// Entity should now be at -50 tokens, so Allow should fail
-	ok, err := limiter.Allow(entity, 1)
-	if err != nil {
-		t.Fatalf("failed to allow: %v", err)
-	}
-	if ok {
+	if limiter.Allow(entity, 1) {
 		t.Fatalf("lewis should have been rejected after being penalized")
 	}
 }
@@ -70,18 +59,12 @@ func TestPenalizeUnknownEntity(t *testing.T) {
 	entity := "unknown"
 
 	// Penalize an entity that has never been seen before
-	if err := limiter.Penalize(entity, 50); err != nil {
-		t.Fatalf("failed to penalize unknown entity: %v", err)
-	}
+	limiter.Penalize(entity, 50)
 
 	// Entity should have a bucket now, with 100 - 50 = 50 tokens
 	allowed := 0
 	for {
-		ok, err := limiter.Allow(entity, 1)
-		if err != nil {
-			t.Fatalf("failed to allow: %v", err)
-		}
-		if !ok {
+		if !limiter.Allow(entity, 1) {
 			break
 		}
 		allowed++
@@ -107,13 +90,64 @@ func TestConcurrency(t *testing.T) {
 	wg := sync.WaitGroup{}
 	wg.Add(10_000)
 
-	for range 10_000 {
+	for i := 0; i < 10_000; i++ {
 		go func() {
 			defer wg.Done()
-			if _, err := limiter.Allow(entity, 1); err != nil {
-				t.Errorf("failed to allow: %v", err)
-			}
+			limiter.Allow(entity, 1)
 		}()
 	}
 	wg.Wait()
 }
+
+func TestBoundedLimiterEvicts(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	limiter := NewBoundedLimiter[string](refiller, 2)
+
+	var evicted []string
+	limiter.OnEvict(func(entity string, _ *Bucket) {
+		evicted = append(evicted, entity)
+	})
+
+	limiter.Allow("alice", 1)
+	limiter.Allow("bob", 1)
+	limiter.Allow("carol", 1) // should evict alice, the least-recently-used
+
+	if limiter.Len() != 2 {
+		t.Fatalf("expected 2 tracked entities, got %d", limiter.Len())
+	}
+	if len(evicted) != 1 || evicted[0] != "alice" {
+		t.Fatalf("expected alice to be evicted, got %v", evicted)
+	}
+
+	// alice was evicted, so she is no longer tracked and Balance reports 0
+	if balance := limiter.Balance("alice"); balance != 0 {
+		t.Fatalf("expected untracked alice to report 0 balance, got %v", balance)
+	}
+}
+
+func TestBoundedLimiterTouchOnBalance(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	limiter := NewBoundedLimiter[string](refiller, 2)
+
+	limiter.Allow("alice", 1)
+	limiter.Allow("bob", 1)
+	limiter.Balance("alice") // touch alice so she becomes the most-recently-used
+	limiter.Allow("carol", 1)
+
+	if limiter.Balance("bob") != 0 {
+		t.Fatalf("expected bob to have been evicted after being touched least recently")
+	}
+	if limiter.Balance("alice") == 0 {
+		t.Fatalf("expected alice to survive eviction after being touched via Balance")
+	}
+}
@@ -0,0 +1,218 @@
+// The package ratenet wraps [io.Reader], [io.Writer] and [net.Listener] so
+// that byte throughput is shaped by a [rate.Limiter], turning the rate
+// package into a drop-in bandwidth limiter in addition to a request counter.
+package ratenet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pippellia-btc/rate"
+)
+
+// debit waits until cost can be afforded and deducts it from entity's
+// bucket, splitting cost into smaller pieces if it exceeds what the bucket
+// can ever hold in one go (e.g. a caller's 32 KiB read buffer against a
+// 10 KiB/s limiter). Without this, a single oversized debit would return
+// [rate.ErrCostTooLarge] forever, even though the bytes already reached the
+// caller.
+//
+// ctx governs how long debit is willing to block; the caller decides whether
+// that's context.Background() (wait indefinitely) or a deadline derived from
+// e.g. a net.Conn's read/write deadline.
+func debit[K comparable](ctx context.Context, l *rate.Limiter[K], entity K, cost float64) error {
+	chunk := cost
+
+	for cost > 0 {
+		if chunk > cost {
+			chunk = cost
+		}
+
+		err := l.Wait(ctx, entity, chunk)
+		if err == nil {
+			cost -= chunk
+			continue
+		}
+		if !errors.Is(err, rate.ErrCostTooLarge) || chunk <= 1 {
+			return err
+		}
+		chunk /= 2
+	}
+	return nil
+}
+
+// Reader wraps an [io.Reader], debiting the number of bytes read from
+// entity's bucket on every call and blocking via [rate.Limiter.Wait] until
+// the budget allows it. A debit larger than the bucket can ever hold is
+// split into smaller pieces automatically.
+type Reader[K comparable] struct {
+	limiter *rate.Limiter[K]
+	entity  K
+	r       io.Reader
+
+	// deadline, if non-nil, is consulted on every Read to bound how long the
+	// debit is willing to block; used by shapedConn to honor SetReadDeadline.
+	// Reader built via NewReader waits indefinitely, matching plain io.Reader
+	// semantics.
+	deadline func() time.Time
+}
+
+// NewReader returns an [io.Reader] that shapes r's throughput against entity's
+// bucket in l.
+func NewReader[K comparable](l *rate.Limiter[K], entity K, r io.Reader) io.Reader {
+	return &Reader[K]{limiter: l, entity: entity, r: r}
+}
+
+func (r *Reader[K]) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		ctx, cancel := deadlineCtx(r.deadline)
+		werr := debit(ctx, r.limiter, r.entity, float64(n))
+		cancel()
+		if werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Writer wraps an [io.Writer], debiting the number of bytes written from
+// entity's bucket on every call and blocking via [rate.Limiter.Wait] until
+// the budget allows it. A debit larger than the bucket can ever hold is
+// split into smaller pieces automatically.
+type Writer[K comparable] struct {
+	limiter *rate.Limiter[K]
+	entity  K
+	w       io.Writer
+
+	// deadline, if non-nil, is consulted on every Write to bound how long the
+	// debit is willing to block; used by shapedConn to honor SetWriteDeadline.
+	// Writer built via NewWriter waits indefinitely, matching plain io.Writer
+	// semantics.
+	deadline func() time.Time
+}
+
+// NewWriter returns an [io.Writer] that shapes w's throughput against
+// entity's bucket in l.
+func NewWriter[K comparable](l *rate.Limiter[K], entity K, w io.Writer) io.Writer {
+	return &Writer[K]{limiter: l, entity: entity, w: w}
+}
+
+// deadlineCtx builds a context bounded by deadline(), or a background
+// context if deadline is nil or reports the zero time (no deadline set). The
+// returned cancel func must always be called once the context is no longer
+// needed, per the context package's contract.
+func deadlineCtx(deadline func() time.Time) (context.Context, context.CancelFunc) {
+	if deadline != nil {
+		if t := deadline(); !t.IsZero() {
+			return context.WithDeadline(context.Background(), t)
+		}
+	}
+	return context.Background(), func() {}
+}
+
+func (w *Writer[K]) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		ctx, cancel := deadlineCtx(w.deadline)
+		werr := debit(ctx, w.limiter, w.entity, float64(n))
+		cancel()
+		if werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Listener wraps a [net.Listener], shaping the upload and download bandwidth
+// of every accepted connection against l, keyed by keyFn.
+type Listener[K comparable] struct {
+	net.Listener
+	limiter *rate.Limiter[K]
+	keyFn   func(net.Conn) K
+}
+
+// NewListener returns a [net.Listener] that gives each accepted connection
+// per-entity upload/download shaping in a few lines, mirroring the
+// --limit-upload/--limit-download pattern common in backup tools. keyFn
+// derives the entity (e.g. the remote IP) from the accepted connection.
+func NewListener[K comparable](l *rate.Limiter[K], keyFn func(net.Conn) K, inner net.Listener) net.Listener {
+	return &Listener[K]{Listener: inner, limiter: l, keyFn: keyFn}
+}
+
+func (ln *Listener[K]) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	entity := ln.keyFn(conn)
+	sc := &shapedConn[K]{Conn: conn}
+	reader := &Reader[K]{limiter: ln.limiter, entity: entity, r: conn, deadline: sc.getReadDeadline}
+	writer := &Writer[K]{limiter: ln.limiter, entity: entity, w: conn, deadline: sc.getWriteDeadline}
+	sc.reader, sc.writer = reader, writer
+	return sc, nil
+}
+
+// shapedConn wraps a [net.Conn], routing Read and Write through the
+// bandwidth-shaped Reader and Writer while leaving every other method
+// (Close, addresses, ...) to the underlying connection.
+//
+// SetReadDeadline/SetWriteDeadline are overridden to also remember the
+// deadline locally, so that a blocked Read/Write - waiting on the limiter,
+// not just on the underlying conn - still returns once the deadline passes,
+// instead of ignoring it like a naive wrapper would.
+type shapedConn[K comparable] struct {
+	net.Conn
+	reader io.Reader
+	writer io.Writer
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (c *shapedConn[K]) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c *shapedConn[K]) Write(p []byte) (int, error) {
+	return c.writer.Write(p)
+}
+
+func (c *shapedConn[K]) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *shapedConn[K]) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *shapedConn[K]) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetWriteDeadline(t)
+}
+
+func (c *shapedConn[K]) getReadDeadline() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readDeadline
+}
+
+func (c *shapedConn[K]) getWriteDeadline() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writeDeadline
+}
@@ -0,0 +1,190 @@
+package ratenet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pippellia-btc/rate"
+)
+
+func TestReaderShapesThroughput(t *testing.T) {
+	refiller := rate.FlatRefiller[string]{
+		InitialTokens:     5,
+		MaxTokens:         5,
+		TokensPerInterval: 5,
+		Interval:          50 * time.Millisecond,
+	}
+
+	limiter := rate.NewLimiter[string](refiller)
+	src := bytes.NewReader(make([]byte, 10))
+
+	r := NewReader(limiter, "lewis", src)
+	buf := make([]byte, 5)
+
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("failed to read first chunk: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("failed to read second chunk: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected the second 5 bytes to be throttled, took %v", elapsed)
+	}
+}
+
+func TestWriterShapesThroughput(t *testing.T) {
+	refiller := rate.FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	limiter := rate.NewLimiter[string](refiller)
+	var dst bytes.Buffer
+
+	w := NewWriter(limiter, "lewis", &dst)
+
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if dst.String() != "hello" {
+		t.Fatalf("expected 'hello' to reach the underlying writer, got %q", dst.String())
+	}
+	if balance := limiter.Balance("lewis"); balance != 95 {
+		t.Fatalf("expected 95 tokens remaining after writing 5 bytes, got %v", balance)
+	}
+}
+
+// onceListener is a net.Listener that hands out a single, pre-made net.Conn
+// then returns net.ErrClosed, just enough to drive NewListener's Accept in tests.
+type onceListener struct {
+	conn net.Conn
+	done chan struct{}
+}
+
+func (l *onceListener) Accept() (net.Conn, error) {
+	select {
+	case <-l.done:
+		return nil, net.ErrClosed
+	default:
+		close(l.done)
+		return l.conn, nil
+	}
+}
+
+func (l *onceListener) Close() error   { return nil }
+func (l *onceListener) Addr() net.Addr { return l.conn.LocalAddr() }
+
+func TestShapedConnHonorsReadDeadline(t *testing.T) {
+	refiller := rate.FlatRefiller[string]{
+		InitialTokens:     0,
+		MaxTokens:         10,
+		TokensPerInterval: 1,
+		Interval:          10 * time.Second,
+	}
+	limiter := rate.NewLimiter[string](refiller)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	ln := NewListener(limiter, func(net.Conn) string { return "lewis" },
+		&onceListener{conn: server, done: make(chan struct{})})
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("failed to accept: %v", err)
+	}
+	defer conn.Close()
+
+	go client.Write([]byte("x"))
+
+	conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected Read to fail once the balance-less debit blocks past the deadline")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Read ignored SetReadDeadline and hung")
+	}
+}
+
+func TestShapedConnHonorsWriteDeadline(t *testing.T) {
+	refiller := rate.FlatRefiller[string]{
+		InitialTokens:     0,
+		MaxTokens:         10,
+		TokensPerInterval: 1,
+		Interval:          10 * time.Second,
+	}
+	limiter := rate.NewLimiter[string](refiller)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	ln := NewListener(limiter, func(net.Conn) string { return "lewis" },
+		&onceListener{conn: server, done: make(chan struct{})})
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("failed to accept: %v", err)
+	}
+	defer conn.Close()
+
+	// drain the client side so the underlying conn.Write completes
+	// immediately; it's the rate-limited debit afterward that must be
+	// bounded by SetWriteDeadline.
+	go io.Copy(io.Discard, client)
+
+	conn.SetWriteDeadline(time.Now().Add(20 * time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Write([]byte("x"))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected Write to fail once the balance-less debit blocks past the deadline")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Write ignored SetWriteDeadline and hung")
+	}
+}
+
+func TestReadLargerThanMaxTokensIsChunked(t *testing.T) {
+	refiller := rate.FlatRefiller[string]{
+		InitialTokens:     1000,
+		MaxTokens:         1000,
+		TokensPerInterval: 1000,
+		Interval:          time.Millisecond,
+	}
+
+	limiter := rate.NewLimiter[string](refiller)
+	src := bytes.NewReader(make([]byte, 5000))
+
+	r := NewReader(limiter, "lewis", src)
+
+	// a single Read of 5000 bytes exceeds MaxTokens; it must still succeed by
+	// debiting in chunks instead of returning rate.ErrCostTooLarge forever.
+	n, err := io.ReadFull(r, make([]byte, 5000))
+	if err != nil {
+		t.Fatalf("expected a large read to succeed via chunked debits, got: %v", err)
+	}
+	if n != 5000 {
+		t.Fatalf("expected 5000 bytes read, got %d", n)
+	}
+}
@@ -1,6 +1,9 @@
 package rate
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Refiller encapsulates the behaviour of the refill policy of the limiter.
 // Users of this package can use custom refill policies by implementing this interface.
@@ -15,6 +18,38 @@ type Refiller[K comparable] interface {
 	Refill(entity K, bucket *Bucket)
 }
 
+// TimeUntiler is an optional interface a [Refiller] can implement to let
+// [Limiter.Wait] and [Limiter.Reserve] compute an exact wait duration instead
+// of falling back to polling. It is detected with a type assertion, so
+// implementing it is never required.
+type TimeUntiler[K comparable] interface {
+	// TimeUntil returns how long until bucket will be able to afford cost,
+	// assuming it keeps being refilled at the policy's normal rate. It should
+	// return maxWait if cost can never be satisfied (e.g. it exceeds the
+	// bucket's maximum capacity).
+	TimeUntil(entity K, bucket *Bucket, cost float64) time.Duration
+}
+
+// maxWait is returned by TimeUntil to signal that a cost can never be
+// satisfied, no matter how long the caller waits.
+const maxWait = time.Duration(math.MaxInt64)
+
+// Denier is an optional interface a [Refiller] can implement to react when
+// [Limiter.Allow] denies a request. It is detected with a type assertion, so
+// implementing it is never required.
+//
+// The canonical use is "cooldown" mode (see [FlatRefiller]'s Cooldown field):
+// instead of leaving a denied entity's balance untouched, deduct the cost
+// anyway, so a repeat offender stays locked out until the balance climbs
+// back above zero rather than being allowed again as soon as the next
+// refill lands.
+type Denier[K comparable] interface {
+	// OnDeny is called while still holding bucket.mu, after Refill has
+	// already run and Limiter.Allow has determined bucket cannot afford
+	// cost. It may mutate bucket.
+	OnDeny(entity K, bucket *Bucket, cost float64)
+}
+
 // NoRefill is a Refiller that does not refill the bucket after being created.
 // It is useful for cases where the bucket is not supposed to be refilled.
 type NoRefill[K comparable] struct {
@@ -37,6 +72,20 @@ type FlatRefiller[K comparable] struct {
 	MaxTokens         float64
 	TokensPerInterval float64
 	Interval          time.Duration
+
+	// Cooldown enables "sticky" denial: when Allow denies a request, the
+	// cost is deducted anyway, driving the balance negative instead of
+	// leaving it untouched. A repeat offender then stays locked out until
+	// the balance refills back above zero, rather than being allowed again
+	// as soon as the next refill lands.
+	Cooldown bool
+
+	// MinBalance bounds how negative Cooldown is allowed to drive a bucket,
+	// e.g. -MaxTokens. It defaults to 0, meaning a denied request still
+	// drains any remaining balance down to 0 (rather than leaving it
+	// untouched, as non-Cooldown Allow does); set it negative to let
+	// Cooldown actually push the balance below zero.
+	MinBalance float64
 }
 
 func (r FlatRefiller[K]) NewBucket(_ K) *Bucket {
@@ -58,3 +107,27 @@ func (r FlatRefiller[K]) Refill(_ K, b *Bucket) {
 	b.Tokens = min(r.MaxTokens, b.Tokens+float64(refills)*r.TokensPerInterval)
 	b.LastRefill = b.LastRefill.Add(refills * r.Interval)
 }
+
+// TimeUntil implements [TimeUntiler], returning how long until bucket holds at
+// least cost tokens given the refiller's rate.
+func (r FlatRefiller[K]) TimeUntil(_ K, b *Bucket, cost float64) time.Duration {
+	deficit := cost - b.Tokens
+	if deficit <= 0 {
+		return 0
+	}
+	if r.Interval <= 0 || r.TokensPerInterval <= 0 || cost > r.MaxTokens {
+		return maxWait
+	}
+
+	rate := r.TokensPerInterval / float64(r.Interval)
+	return time.Duration(deficit / rate)
+}
+
+// OnDeny implements [Denier]. When Cooldown is enabled, it deducts cost from
+// bucket even though the request was denied, clamped at MinBalance.
+func (r FlatRefiller[K]) OnDeny(_ K, b *Bucket, cost float64) {
+	if !r.Cooldown {
+		return
+	}
+	b.Tokens = max(r.MinBalance, b.Tokens-cost)
+}
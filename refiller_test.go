@@ -48,3 +48,62 @@ func TestFlatRefill(t *testing.T) {
 		})
 	}
 }
+
+func TestCooldown(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     10,
+		MaxTokens:         10,
+		TokensPerInterval: 10,
+		Interval:          time.Hour,
+		Cooldown:          true,
+		MinBalance:        -10,
+	}
+
+	limiter := NewLimiter[string](refiller)
+	entity := "lewis"
+
+	// drain the bucket
+	for i := 0; i < 10; i++ {
+		if !limiter.Allow(entity, 1) {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+
+	// denied requests should drive the balance negative instead of leaving it at 0
+	if limiter.Allow(entity, 1) {
+		t.Fatalf("expected request to be denied once the bucket is empty")
+	}
+	if balance := limiter.Balance(entity); balance != -1 {
+		t.Fatalf("expected cooldown to deduct on denial, got balance %v", balance)
+	}
+
+	// MinBalance must clamp further deductions
+	for i := 0; i < 20; i++ {
+		limiter.Allow(entity, 1)
+	}
+	if balance := limiter.Balance(entity); balance != -10 {
+		t.Fatalf("expected balance to be clamped at MinBalance, got %v", balance)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     10,
+		MaxTokens:         10,
+		TokensPerInterval: 10,
+		Interval:          time.Hour,
+	}
+
+	limiter := NewLimiter[string](refiller)
+	entity := "lewis"
+
+	if delay := limiter.RetryAfter(entity, 5); delay != 0 {
+		t.Fatalf("expected a fresh bucket to need no retry, got %v", delay)
+	}
+
+	limiter.Penalize(entity, 10)
+
+	if delay := limiter.RetryAfter(entity, 5); delay <= 0 {
+		t.Fatalf("expected a depleted bucket to report a positive retry delay, got %v", delay)
+	}
+}
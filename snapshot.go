@@ -0,0 +1,200 @@
+package rate
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// bucketJSON is the wire representation of a [Bucket]. Multi-dimensional
+// buckets created by a [MultiRefiller] are not representable this way and
+// marshal their top-level (unused) Tokens/LastRefill instead of their
+// sub-buckets; use [Limiter.Snapshot]/[Limiter.Load], whose [Entry] type
+// does capture every dimension, to persist those.
+type bucketJSON struct {
+	Tokens     float64   `json:"tokens"`
+	LastRefill time.Time `json:"last_refill"`
+}
+
+// MarshalJSON implements [json.Marshaler], reading Tokens and LastRefill
+// under the bucket's own lock so a concurrent Allow/Penalize/Reward cannot
+// race with the encoding.
+func (b *Bucket) MarshalJSON() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return json.Marshal(bucketJSON{Tokens: b.Tokens, LastRefill: b.LastRefill})
+}
+
+// UnmarshalJSON implements [json.Unmarshaler], writing Tokens and LastRefill
+// under the bucket's own lock.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var bj bucketJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.Tokens = bj.Tokens
+	b.LastRefill = bj.LastRefill
+	b.mu.Unlock()
+	return nil
+}
+
+// dimEntry is the serializable state of a single dimension of a
+// multi-dimensional bucket created by a [MultiRefiller].
+type dimEntry struct {
+	Tokens     float64   `json:"tokens"`
+	LastRefill time.Time `json:"last_refill"`
+}
+
+// Entry is a serializable snapshot of a single entity's bucket, as produced
+// by [Limiter.Snapshot]/[Limiter.SnapshotWriter] and consumed by
+// [Limiter.Load]. For an ordinary, single-dimension bucket, Tokens/LastRefill
+// hold its state and Dims is nil. For a multi-dimensional bucket created by a
+// [MultiRefiller], Dims holds one entry per sub-bucket, in the same order as
+// the MultiRefiller's Refillers, and Tokens/LastRefill are unused.
+type Entry[K comparable] struct {
+	Key        K          `json:"key"`
+	Tokens     float64    `json:"tokens"`
+	LastRefill time.Time  `json:"last_refill"`
+	Dims       []dimEntry `json:"dims,omitempty"`
+}
+
+// entryFor builds key's Entry from bucket. Callers must hold bucket.mu.
+func entryFor[K comparable](key K, bucket *Bucket) Entry[K] {
+	if bucket.multi == nil {
+		return Entry[K]{Key: key, Tokens: bucket.Tokens, LastRefill: bucket.LastRefill}
+	}
+
+	dims := make([]dimEntry, len(bucket.multi.Buckets))
+	for i, sub := range bucket.multi.Buckets {
+		dims[i] = dimEntry{Tokens: sub.Tokens, LastRefill: sub.LastRefill}
+	}
+	return Entry[K]{Key: key, Dims: dims}
+}
+
+// bucketFor builds a fresh Bucket from entry, reconstructing a
+// multi-dimensional bucket when entry.Dims is set.
+func bucketFor[K comparable](entry Entry[K]) *Bucket {
+	if entry.Dims == nil {
+		return &Bucket{Tokens: entry.Tokens, LastRefill: entry.LastRefill}
+	}
+
+	sub := make([]*Bucket, len(entry.Dims))
+	for i, d := range entry.Dims {
+		sub[i] = &Bucket{Tokens: d.Tokens, LastRefill: d.LastRefill}
+	}
+	return &Bucket{multi: &MultiBucket{Buckets: sub}}
+}
+
+// applyEntry overwrites bucket's state with entry's, reconstructing a
+// multi-dimensional bucket when entry.Dims is set. Callers must hold
+// bucket.mu.
+func applyEntry[K comparable](bucket *Bucket, entry Entry[K]) {
+	if entry.Dims == nil {
+		bucket.multi = nil
+		bucket.Tokens = entry.Tokens
+		bucket.LastRefill = entry.LastRefill
+		return
+	}
+
+	sub := make([]*Bucket, len(entry.Dims))
+	for i, d := range entry.Dims {
+		sub[i] = &Bucket{Tokens: d.Tokens, LastRefill: d.LastRefill}
+	}
+	bucket.Tokens = 0
+	bucket.LastRefill = time.Time{}
+	bucket.multi = &MultiBucket{Buckets: sub}
+}
+
+// Snapshot returns a point-in-time copy of every tracked entity's bucket
+// state, suitable for persisting across restarts so that penalty state (and,
+// on a bounded limiter, abuse history) is not reset on every deploy.
+// Multi-dimensional buckets created by a [MultiRefiller] are captured in
+// full, via [Entry.Dims].
+//
+// For limiters tracking millions of entities, prefer [Limiter.SnapshotWriter],
+// which does not hold the limiter's map lock for the whole operation.
+func (l *Limiter[K]) Snapshot() []Entry[K] {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := make([]Entry[K], 0, len(l.buckets))
+	for key, bucket := range l.buckets {
+		bucket.mu.Lock()
+		entries = append(entries, entryFor(key, bucket))
+		bucket.mu.Unlock()
+	}
+	return entries
+}
+
+// SnapshotWriter streams the same data as [Limiter.Snapshot] to w as
+// newline-delimited JSON [Entry] objects, one per tracked entity. Unlike
+// Snapshot, it only ever holds a single bucket's lock at a time -- never the
+// limiter's map lock for the whole walk -- so snapshotting millions of
+// entries does not stall Allow/Penalize/Reward on the hot path.
+func (l *Limiter[K]) SnapshotWriter(w io.Writer) error {
+	l.mu.RLock()
+	keys := make([]K, 0, len(l.buckets))
+	for key := range l.buckets {
+		keys = append(keys, key)
+	}
+	l.mu.RUnlock()
+
+	enc := json.NewEncoder(w)
+	for _, key := range keys {
+		l.mu.RLock()
+		bucket, exists := l.buckets[key]
+		l.mu.RUnlock()
+		if !exists {
+			// evicted or otherwise removed since the key list was taken
+			continue
+		}
+
+		bucket.mu.Lock()
+		entry := entryFor(key, bucket)
+		bucket.mu.Unlock()
+
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load restores buckets from entries, e.g. the output of a prior Snapshot
+// after a restart, without resetting penalty state. Entities already tracked
+// are overwritten, including switching between an ordinary and a
+// multi-dimensional bucket if entry.Dims says so; buckets for keys not
+// present in entries are left untouched. On a bounded limiter, loading can
+// trigger LRU eviction if entries pushes the tracked count past maxEntries.
+func (l *Limiter[K]) Load(entries []Entry[K]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, entry := range entries {
+		bucket, exists := l.buckets[entry.Key]
+		if !exists {
+			bucket = bucketFor(entry)
+			l.buckets[entry.Key] = bucket
+			if l.maxEntries > 0 {
+				l.elements[entry.Key] = l.order.PushFront(entry.Key)
+			}
+			continue
+		}
+
+		if l.maxEntries > 0 {
+			l.touch(entry.Key)
+		}
+
+		bucket.mu.Lock()
+		applyEntry(bucket, entry)
+		bucket.mu.Unlock()
+	}
+
+	if l.maxEntries > 0 {
+		for len(l.buckets) > l.maxEntries {
+			l.evictLRU()
+		}
+	}
+}
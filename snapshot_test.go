@@ -0,0 +1,184 @@
+package rate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSnapshotAndLoad(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	src := NewLimiter[string](refiller)
+	src.Allow("lewis", 30)
+	src.Allow("hamilton", 10)
+
+	entries := src.Snapshot()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	dst := NewLimiter[string](refiller)
+	dst.Load(entries)
+
+	if balance := dst.Balance("lewis"); balance != 70 {
+		t.Fatalf("expected restored balance of 70, got %v", balance)
+	}
+	if balance := dst.Balance("hamilton"); balance != 90 {
+		t.Fatalf("expected restored balance of 90, got %v", balance)
+	}
+}
+
+func TestSnapshotWriterStreamsEntries(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens: 50,
+		MaxTokens:     50,
+	}
+
+	limiter := NewLimiter[string](refiller)
+	limiter.Allow("lewis", 5)
+
+	var buf bytes.Buffer
+	if err := limiter.SnapshotWriter(&buf); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+
+	var entry Entry[string]
+	if err := json.NewDecoder(&buf).Decode(&entry); err != nil {
+		t.Fatalf("failed to decode streamed entry: %v", err)
+	}
+	if entry.Key != "lewis" || entry.Tokens != 45 {
+		t.Fatalf("unexpected streamed entry: %+v", entry)
+	}
+}
+
+func TestSnapshotAndLoadMultiBucket(t *testing.T) {
+	refiller := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+			FlatRefiller[string]{InitialTokens: 1000, MaxTokens: 1000},
+		},
+	}
+
+	src := NewLimiter[string](refiller)
+	if !src.AllowN("lewis", 4, 400) {
+		t.Fatalf("expected first AllowN to succeed")
+	}
+
+	entries := src.Snapshot()
+	if len(entries) != 1 || len(entries[0].Dims) != 2 {
+		t.Fatalf("expected 1 entry with 2 dims, got %+v", entries)
+	}
+
+	dst := NewLimiter[string](refiller)
+	dst.Load(entries)
+
+	// lewis should round-trip with 6 ops and 600 bytes remaining; a request
+	// for 7 ops must be denied without touching either sub-bucket, and one
+	// for 6 must succeed without panicking.
+	if dst.AllowN("lewis", 7, 1) {
+		t.Fatalf("expected AllowN to deny a cost exceeding the restored ops balance")
+	}
+	if !dst.AllowN("lewis", 6, 600) {
+		t.Fatalf("expected AllowN to allow the exact restored balance")
+	}
+}
+
+func TestLoadOverwritesExistingOrdinaryBucket(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	src := NewLimiter[string](refiller)
+	src.Allow("lewis", 30)
+
+	dst := NewLimiter[string](refiller)
+	dst.Allow("lewis", 99) // dst already tracks "lewis", with a balance Load must overwrite
+
+	dst.Load(src.Snapshot())
+
+	if balance := dst.Balance("lewis"); balance != 70 {
+		t.Fatalf("expected Load to overwrite the existing balance with 70, got %v", balance)
+	}
+}
+
+func TestLoadSwitchesOrdinaryBucketToMultiDimensional(t *testing.T) {
+	multi := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+			FlatRefiller[string]{InitialTokens: 1000, MaxTokens: 1000},
+		},
+	}
+
+	src := NewLimiter[string](multi)
+	if !src.AllowN("lewis", 4, 400) {
+		t.Fatalf("expected first AllowN to succeed")
+	}
+	entries := src.Snapshot()
+
+	// dst already tracks "lewis" as an ordinary, single-dimension bucket.
+	dst := NewLimiter[string](multi)
+	flat := FlatRefiller[string]{InitialTokens: 100, MaxTokens: 100}
+	dst.buckets["lewis"] = flat.NewBucket("lewis")
+
+	dst.Load(entries)
+
+	if dst.AllowN("lewis", 7, 1) {
+		t.Fatalf("expected AllowN to deny a cost exceeding the restored ops balance")
+	}
+	if !dst.AllowN("lewis", 6, 600) {
+		t.Fatalf("expected AllowN to allow the exact restored balance after switching to multi-dimensional")
+	}
+}
+
+func TestLoadSwitchesMultiDimensionalBucketToOrdinary(t *testing.T) {
+	flatRefiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	src := NewLimiter[string](flatRefiller)
+	src.Allow("lewis", 30)
+	entries := src.Snapshot()
+
+	// dst already tracks "lewis" as a multi-dimensional bucket.
+	multi := MultiRefiller[string]{
+		Refillers: []Refiller[string]{
+			FlatRefiller[string]{InitialTokens: 10, MaxTokens: 10},
+		},
+	}
+	dst := NewLimiter[string](flatRefiller)
+	dst.buckets["lewis"] = multi.NewBucket("lewis")
+
+	dst.Load(entries)
+
+	if balance := dst.Balance("lewis"); balance != 70 {
+		t.Fatalf("expected Load to switch the bucket to an ordinary one with balance 70, got %v", balance)
+	}
+}
+
+func TestBucketJSONRoundTrip(t *testing.T) {
+	b := &Bucket{Tokens: 42, LastRefill: time.Unix(1700000000, 0).UTC()}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("failed to marshal bucket: %v", err)
+	}
+
+	var restored Bucket
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("failed to unmarshal bucket: %v", err)
+	}
+
+	if restored.Tokens != b.Tokens || !restored.LastRefill.Equal(b.LastRefill) {
+		t.Fatalf("expected round-tripped bucket %+v, got %+v", b, &restored)
+	}
+}
@@ -0,0 +1,190 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCostTooLarge is returned by [Limiter.Wait] when cost can never be
+// satisfied by the entity's bucket, no matter how long the caller waits
+// (e.g. it exceeds a [FlatRefiller]'s MaxTokens). Use errors.Is to check for
+// it.
+var ErrCostTooLarge = errors.New("rate: cost can never be satisfied")
+
+// pollInterval is the wait used by [Limiter.Wait] and [Limiter.Reserve]
+// between checks when the configured [Refiller] does not implement
+// [TimeUntiler], since the limiter then has no way to compute exactly when
+// enough tokens will exist.
+const pollInterval = 50 * time.Millisecond
+
+// timeUntil returns how long to wait until bucket can afford cost, using the
+// Refiller's TimeUntil method if implemented, or pollInterval as a fallback.
+// Callers must hold bucket.mu.
+func (l *Limiter[K]) timeUntil(entity K, bucket *Bucket, cost float64) time.Duration {
+	if tu, ok := l.refiller.(TimeUntiler[K]); ok {
+		return tu.TimeUntil(entity, bucket, cost)
+	}
+	return pollInterval
+}
+
+// Wait blocks until the entity's bucket can afford cost, then deducts it, or
+// returns ctx.Err() if ctx is done first. It panics if the cost is negative,
+// or if entity's bucket was created by a [MultiRefiller], which AllowN
+// checks atomically rather than through a single balance.
+//
+// If the configured [Refiller] implements [TimeUntiler], Wait sleeps for the
+// exact computed duration, and returns an error wrapping [ErrCostTooLarge]
+// without blocking if TimeUntil reports that cost can never be satisfied.
+// Otherwise it polls every pollInterval; such a cost is then not detected,
+// and Wait blocks until ctx is done.
+func (l *Limiter[K]) Wait(ctx context.Context, entity K, cost float64) error {
+	if cost < 0 {
+		panic("limiter.Wait: cost must be non-negative")
+	}
+	if cost == 0 {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for {
+		bucket := l.getOrCreate(entity)
+
+		bucket.mu.Lock()
+		if bucket.multi != nil {
+			bucket.mu.Unlock()
+			panic("limiter.Wait: entity's bucket was created by a MultiRefiller; use AllowN instead")
+		}
+		l.refiller.Refill(entity, bucket)
+		if bucket.Tokens >= cost {
+			bucket.Tokens -= cost
+			bucket.mu.Unlock()
+			return nil
+		}
+		delay := l.timeUntil(entity, bucket, cost)
+		bucket.mu.Unlock()
+
+		if delay == maxWait {
+			return fmt.Errorf("%w: cost %v", ErrCostTooLarge, cost)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			// loop back around to re-check and refill
+		}
+	}
+}
+
+// RetryAfter returns how long until entity's bucket could afford cost, given
+// its current balance and the Refiller's configured rate, without consuming
+// any tokens. It returns 0 if cost is already affordable. This is meant for
+// servers to compute a correct Retry-After header after denying a request,
+// e.g. one driven into a [FlatRefiller] Cooldown. If the Refiller does not
+// implement [TimeUntiler], an unaffordable cost yields pollInterval rather
+// than a computed duration.
+//
+// It panics if entity's bucket was created by a [MultiRefiller], which has no
+// single balance to check a time-until-affordable against.
+func (l *Limiter[K]) RetryAfter(entity K, cost float64) time.Duration {
+	bucket := l.getOrCreate(entity)
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if bucket.multi != nil {
+		panic("limiter.RetryAfter: entity's bucket was created by a MultiRefiller; use AllowN instead")
+	}
+
+	l.refiller.Refill(entity, bucket)
+	if bucket.Tokens >= cost {
+		return 0
+	}
+	return l.timeUntil(entity, bucket, cost)
+}
+
+// Reservation is the outcome of a call to [Limiter.Reserve]: whether the cost
+// can ever be satisfied, and how long the caller should wait before acting on
+// it. The reserved cost is deducted immediately, so callers that decide not
+// to proceed should call [Reservation.Cancel] to credit it back.
+type Reservation[K comparable] struct {
+	ok       bool
+	delay    time.Duration
+	limiter  *Limiter[K]
+	entity   K
+	cost     float64
+	canceled *atomic.Bool
+}
+
+// OK reports whether the limiter can ever grant the reserved cost.
+// If false, Delay and Cancel have no meaning and nothing was deducted.
+func (r Reservation[K]) OK() bool {
+	return r.ok
+}
+
+// Delay returns how long the caller should wait before the reserved cost is
+// actually available.
+func (r Reservation[K]) Delay() time.Duration {
+	return r.delay
+}
+
+// Cancel credits the reserved cost back to the entity's bucket, as if the
+// reservation had never happened. Calling Cancel more than once, or on a
+// reservation that is not OK, is a no-op.
+func (r Reservation[K]) Cancel() {
+	if !r.ok || r.canceled.Swap(true) {
+		return
+	}
+	r.limiter.add(r.entity, r.cost)
+}
+
+// Reserve immediately deducts cost from the entity's bucket, as if the
+// request had been allowed right away, and returns a [Reservation] describing
+// how long the caller must wait before acting on it. This lets a caller
+// decide whether the wait is acceptable before committing to it. The delay is
+// 0 when cost is affordable now; otherwise, if the Refiller does not implement
+// [TimeUntiler], it is pollInterval rather than a computed duration.
+// It panics if the cost is negative, or if entity's bucket was created by a
+// [MultiRefiller], which AllowN checks atomically rather than through a
+// single balance.
+func (l *Limiter[K]) Reserve(entity K, cost float64) Reservation[K] {
+	if cost < 0 {
+		panic("limiter.Reserve: cost must be non-negative")
+	}
+
+	bucket := l.getOrCreate(entity)
+
+	bucket.mu.Lock()
+	if bucket.multi != nil {
+		bucket.mu.Unlock()
+		panic("limiter.Reserve: entity's bucket was created by a MultiRefiller; use AllowN instead")
+	}
+	l.refiller.Refill(entity, bucket)
+	var delay time.Duration
+	if bucket.Tokens < cost {
+		delay = l.timeUntil(entity, bucket, cost)
+	}
+	if delay == maxWait {
+		bucket.mu.Unlock()
+		return Reservation[K]{}
+	}
+	bucket.Tokens -= cost
+	bucket.mu.Unlock()
+
+	return Reservation[K]{
+		ok:       true,
+		delay:    delay,
+		limiter:  l,
+		entity:   entity,
+		cost:     cost,
+		canceled: new(atomic.Bool),
+	}
+}
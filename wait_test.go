@@ -0,0 +1,90 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitSucceeds(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     0,
+		MaxTokens:         10,
+		TokensPerInterval: 10,
+		Interval:          50 * time.Millisecond,
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.Wait(ctx, "lewis", 5); err != nil {
+		t.Fatalf("failed to wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected Wait to block until tokens refilled, took %v", elapsed)
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		MaxTokens:         10,
+		TokensPerInterval: 1,
+		Interval:          time.Hour,
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "lewis", 5); err == nil {
+		t.Fatalf("expected Wait to return an error once ctx is done")
+	}
+}
+
+func TestWaitImpossible(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     0,
+		MaxTokens:         10,
+		TokensPerInterval: 1,
+		Interval:          time.Second,
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	if err := limiter.Wait(context.Background(), "lewis", 50); err == nil {
+		t.Fatalf("expected Wait to reject a cost above MaxTokens")
+	}
+}
+
+func TestReserveCancel(t *testing.T) {
+	refiller := FlatRefiller[string]{
+		InitialTokens:     100,
+		MaxTokens:         100,
+		TokensPerInterval: 0,
+	}
+
+	limiter := NewLimiter[string](refiller)
+
+	r := limiter.Reserve("lewis", 30)
+	if !r.OK() {
+		t.Fatalf("expected reservation to be ok")
+	}
+	if balance := limiter.Balance("lewis"); balance != 70 {
+		t.Fatalf("expected 70 tokens after reserving 30, got %v", balance)
+	}
+
+	r.Cancel()
+	if balance := limiter.Balance("lewis"); balance != 100 {
+		t.Fatalf("expected tokens credited back after cancel, got %v", balance)
+	}
+
+	// canceling twice must not double-credit
+	r.Cancel()
+	if balance := limiter.Balance("lewis"); balance != 100 {
+		t.Fatalf("expected a second Cancel to be a no-op, got %v", balance)
+	}
+}